@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thedevsaddam/renderer"
+	"golang.org/x/crypto/bcrypt"
+
+	"simple-todo-app/internal/auth"
+	"simple-todo-app/internal/store"
+)
+
+type (
+	RegisterRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	LoginRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+)
+
+type contextKey string
+
+const userIDContextKey contextKey = "userID"
+
+// userIDFromContext returns the user id injected by RequireUser, or the
+// empty string if the request was never authenticated.
+func userIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDContextKey).(string)
+	return userID
+}
+
+// RequireUser parses the Authorization: Bearer <token> header, verifies
+// it, and injects the user id into the request context. It responds 401
+// if the header is missing or the token is invalid.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		tokenString := strings.TrimPrefix(header, "Bearer ")
+		if tokenString == "" || tokenString == header {
+			rnd.JSON(rw, http.StatusUnauthorized, renderer.M{
+				"message": "missing bearer token",
+			})
+			return
+		}
+
+		userID, err := auth.ParseToken(tokenString, jwtSecret)
+		if err != nil {
+			rnd.JSON(rw, http.StatusUnauthorized, renderer.M{
+				"message": "invalid or expired token",
+			})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDContextKey, userID)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+func authHandlers() http.Handler {
+	router := chi.NewRouter()
+	router.Post("/register", registerUser)
+	router.Post("/login", loginUser)
+	return router
+}
+
+func registerUser(rw http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("failed to decode json data: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+			"message": "could not decode data",
+		})
+		return
+	}
+
+	if req.Username == "" || req.Password == "" {
+		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+			"message": "username and password are required",
+		})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("failed to hash password: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "failed to register user",
+		})
+		return
+	}
+
+	user, err := userStore.Create(r.Context(), req.Username, string(hash))
+	if err != nil {
+		if errors.Is(err, store.ErrUserExists) {
+			rnd.JSON(rw, http.StatusConflict, renderer.M{
+				"message": "username is already taken",
+			})
+			return
+		}
+		log.Printf("failed to create user: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "failed to register user",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	token, err := auth.NewToken(user.ID, jwtSecret)
+	if err != nil {
+		log.Printf("failed to issue token: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "failed to register user",
+		})
+		return
+	}
+
+	rnd.JSON(rw, http.StatusCreated, renderer.M{
+		"message": "user registered successfully",
+		"token":   token,
+	})
+}
+
+func loginUser(rw http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("failed to decode json data: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+			"message": "could not decode data",
+		})
+		return
+	}
+
+	user, err := userStore.GetByUsername(r.Context(), req.Username)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			rnd.JSON(rw, http.StatusUnauthorized, renderer.M{
+				"message": "invalid username or password",
+			})
+			return
+		}
+		log.Printf("failed to look up user: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "failed to log in",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		rnd.JSON(rw, http.StatusUnauthorized, renderer.M{
+			"message": "invalid username or password",
+		})
+		return
+	}
+
+	token, err := auth.NewToken(user.ID, jwtSecret)
+	if err != nil {
+		log.Printf("failed to issue token: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "failed to log in",
+		})
+		return
+	}
+
+	rnd.JSON(rw, http.StatusOK, renderer.M{
+		"message": "logged in successfully",
+		"token":   token,
+	})
+}
@@ -0,0 +1,52 @@
+// Package auth issues and verifies the HS256 JWTs used to authenticate
+// requests to the todo API.
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned by ParseToken when the token is malformed,
+// expired, or signed with the wrong key.
+var ErrInvalidToken = errors.New("auth: invalid token")
+
+// TokenTTL is how long an issued token remains valid.
+const TokenTTL = 24 * time.Hour
+
+type claims struct {
+	UserID string `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// NewToken issues an HS256 JWT for userID, signed with secret.
+func NewToken(userID string, secret []byte) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
+		},
+	})
+	return token.SignedString(secret)
+}
+
+// ParseToken verifies tokenString against secret and returns the user id
+// it was issued for.
+func ParseToken(tokenString string, secret []byte) (string, error) {
+	var c claims
+	token, err := jwt.ParseWithClaims(tokenString, &c, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return secret, nil
+	})
+	if err != nil || !token.Valid {
+		return "", ErrInvalidToken
+	}
+
+	return c.UserID, nil
+}
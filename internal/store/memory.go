@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a map-backed TodoStore for local dev and tests, so the
+// API can boot without a MongoDB instance running.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	todos  map[string]Todo
+	nextID int
+}
+
+// NewMemoryStore returns an empty, ready to use MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		todos: make(map[string]Todo),
+	}
+}
+
+func (s *MemoryStore) filtered(filter Filter) []Todo {
+	matches := make([]Todo, 0, len(s.todos))
+	for _, td := range s.todos {
+		if td.OwnerID != filter.OwnerID {
+			continue
+		}
+		if filter.Completed != nil && td.Completed != *filter.Completed {
+			continue
+		}
+		if filter.Query != "" && !strings.Contains(strings.ToLower(td.Title), strings.ToLower(filter.Query)) {
+			continue
+		}
+		matches = append(matches, td)
+	}
+
+	sortField := filter.Sort
+	if sortField != "created_at" && sortField != "title" {
+		sortField = "created_at"
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		var less bool
+		if sortField == "title" {
+			less = matches[i].Title < matches[j].Title
+		} else {
+			less = matches[i].CreatedAt.Before(matches[j].CreatedAt)
+		}
+		if strings.ToLower(filter.Order) == "desc" {
+			return !less
+		}
+		return less
+	})
+
+	return matches
+}
+
+// List returns the todos matching filter, with pagination applied.
+func (s *MemoryStore) List(ctx context.Context, filter Filter) ([]Todo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := s.filtered(filter)
+
+	if filter.Offset > 0 {
+		if filter.Offset >= int64(len(matches)) {
+			return []Todo{}, nil
+		}
+		matches = matches[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < int64(len(matches)) {
+		matches = matches[:filter.Limit]
+	}
+
+	return matches, nil
+}
+
+// Count returns the number of todos matching filter, ignoring pagination.
+func (s *MemoryStore) Count(ctx context.Context, filter Filter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.filtered(filter))), nil
+}
+
+// Get returns the todo with the given id owned by ownerID, or ErrNotFound.
+func (s *MemoryStore) Get(ctx context.Context, id, ownerID string) (Todo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	td, ok := s.todos[id]
+	if !ok || td.OwnerID != ownerID {
+		return Todo{}, ErrNotFound
+	}
+	return td, nil
+}
+
+// Create stores a new todo built from input and returns it.
+func (s *MemoryStore) Create(ctx context.Context, input CreateInput) (Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	now := time.Now()
+	td := Todo{
+		ID:        strconv.Itoa(s.nextID),
+		OwnerID:   input.OwnerID,
+		Title:     input.Title,
+		Body:      input.Body,
+		Completed: false,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DueDate:   input.DueDate,
+		Priority:  input.Priority,
+		Tags:      input.Tags,
+	}
+	s.todos[td.ID] = td
+	return td, nil
+}
+
+// Update replaces the mutable fields of the todo with the given id owned
+// by ownerID and returns the updated todo, or ErrNotFound.
+func (s *MemoryStore) Update(ctx context.Context, id, ownerID string, input UpdateInput) (Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	td, ok := s.todos[id]
+	if !ok || td.OwnerID != ownerID {
+		return Todo{}, ErrNotFound
+	}
+	td.Title = input.Title
+	td.Body = input.Body
+	td.Completed = input.Completed
+	td.DueDate = input.DueDate
+	td.Priority = input.Priority
+	td.Tags = input.Tags
+	td.UpdatedAt = time.Now()
+	s.todos[id] = td
+	return td, nil
+}
+
+// Delete removes the todo with the given id owned by ownerID, or returns
+// ErrNotFound.
+func (s *MemoryStore) Delete(ctx context.Context, id, ownerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	td, ok := s.todos[id]
+	if !ok || td.OwnerID != ownerID {
+		return ErrNotFound
+	}
+	delete(s.todos, id)
+	return nil
+}
+
+// MemoryUserStore is a map-backed UserStore for local dev and tests.
+type MemoryUserStore struct {
+	mu     sync.RWMutex
+	byName map[string]User
+	nextID int
+}
+
+// NewMemoryUserStore returns an empty, ready to use MemoryUserStore.
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		byName: make(map[string]User),
+	}
+}
+
+// Create stores a new user with the given username and password hash.
+func (s *MemoryUserStore) Create(ctx context.Context, username, passwordHash string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byName[username]; ok {
+		return User{}, ErrUserExists
+	}
+
+	s.nextID++
+	user := User{
+		ID:           strconv.Itoa(s.nextID),
+		Username:     username,
+		PasswordHash: passwordHash,
+	}
+	s.byName[username] = user
+	return user, nil
+}
+
+// GetByUsername returns the user with the given username, or ErrNotFound.
+func (s *MemoryUserStore) GetByUsername(ctx context.Context, username string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.byName[username]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
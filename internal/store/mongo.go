@@ -0,0 +1,267 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore is the production TodoStore backed by a MongoDB collection.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoStore returns a MongoStore backed by the given collection.
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+type todoModel struct {
+	ID        primitive.ObjectID `bson:"id,omitempty"`
+	OwnerID   primitive.ObjectID `bson:"owner_id"`
+	Title     string             `bson:"title"`
+	Body      string             `bson:"body"`
+	Completed bool               `bson:"completed"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+	DueDate   *time.Time         `bson:"due_date,omitempty"`
+	Priority  int                `bson:"priority"`
+	Tags      []string           `bson:"tags"`
+}
+
+func (td todoModel) toTodo() Todo {
+	return Todo{
+		ID:        td.ID.Hex(),
+		OwnerID:   td.OwnerID.Hex(),
+		Title:     td.Title,
+		Body:      td.Body,
+		Completed: td.Completed,
+		CreatedAt: td.CreatedAt,
+		UpdatedAt: td.UpdatedAt,
+		DueDate:   td.DueDate,
+		Priority:  td.Priority,
+		Tags:      td.Tags,
+	}
+}
+
+func mongoFilter(filter Filter) bson.M {
+	query := bson.M{}
+
+	if filter.OwnerID != "" {
+		if oid, err := primitive.ObjectIDFromHex(filter.OwnerID); err == nil {
+			query["owner_id"] = oid
+		}
+	}
+	if filter.Completed != nil {
+		query["completed"] = *filter.Completed
+	}
+	if filter.Query != "" {
+		// QuoteMeta so the query is matched as a literal substring, matching
+		// MemoryStore's strings.Contains semantics, rather than letting the
+		// caller hand Mongo an arbitrary (and potentially catastrophic) regex.
+		query["title"] = bson.M{"$regex": regexp.QuoteMeta(filter.Query), "$options": "i"}
+	}
+
+	return query
+}
+
+func mongoFindOptions(filter Filter) *options.FindOptions {
+	findOpts := options.Find()
+
+	sortField := filter.Sort
+	if sortField != "created_at" && sortField != "title" {
+		sortField = "created_at"
+	}
+	sortOrder := 1
+	if strings.ToLower(filter.Order) == "desc" {
+		sortOrder = -1
+	}
+	findOpts.SetSort(bson.D{{Key: sortField, Value: sortOrder}})
+
+	if filter.Limit > 0 {
+		findOpts.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOpts.SetSkip(filter.Offset)
+	}
+
+	return findOpts
+}
+
+func (s *MongoStore) List(ctx context.Context, filter Filter) ([]Todo, error) {
+	cursor, err := s.collection.Find(ctx, mongoFilter(filter), mongoFindOptions(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	var fromDB []todoModel
+	if err := cursor.All(ctx, &fromDB); err != nil {
+		return nil, err
+	}
+
+	todos := make([]Todo, 0, len(fromDB))
+	for _, td := range fromDB {
+		todos = append(todos, td.toTodo())
+	}
+	return todos, nil
+}
+
+func (s *MongoStore) Count(ctx context.Context, filter Filter) (int64, error) {
+	return s.collection.CountDocuments(ctx, mongoFilter(filter))
+}
+
+func (s *MongoStore) Get(ctx context.Context, id, ownerID string) (Todo, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return Todo{}, ErrNotFound
+	}
+	ownerOID, err := primitive.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return Todo{}, ErrNotFound
+	}
+
+	var td todoModel
+	if err := s.collection.FindOne(ctx, bson.M{"id": oid, "owner_id": ownerOID}).Decode(&td); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Todo{}, ErrNotFound
+		}
+		return Todo{}, err
+	}
+
+	return td.toTodo(), nil
+}
+
+func (s *MongoStore) Create(ctx context.Context, input CreateInput) (Todo, error) {
+	ownerOID, err := primitive.ObjectIDFromHex(input.OwnerID)
+	if err != nil {
+		return Todo{}, ErrNotFound
+	}
+
+	now := time.Now()
+	td := todoModel{
+		ID:        primitive.NewObjectID(),
+		OwnerID:   ownerOID,
+		Title:     input.Title,
+		Body:      input.Body,
+		Completed: false,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DueDate:   input.DueDate,
+		Priority:  input.Priority,
+		Tags:      input.Tags,
+	}
+
+	if _, err := s.collection.InsertOne(ctx, td); err != nil {
+		return Todo{}, err
+	}
+
+	return td.toTodo(), nil
+}
+
+func (s *MongoStore) Update(ctx context.Context, id, ownerID string, input UpdateInput) (Todo, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return Todo{}, ErrNotFound
+	}
+	ownerOID, err := primitive.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return Todo{}, ErrNotFound
+	}
+
+	filter := bson.M{"id": oid, "owner_id": ownerOID}
+	update := bson.M{"$set": bson.M{
+		"title":      input.Title,
+		"body":       input.Body,
+		"completed":  input.Completed,
+		"due_date":   input.DueDate,
+		"priority":   input.Priority,
+		"tags":       input.Tags,
+		"updated_at": time.Now(),
+	}}
+	result := s.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
+
+	var td todoModel
+	if err := result.Decode(&td); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Todo{}, ErrNotFound
+		}
+		return Todo{}, err
+	}
+
+	return td.toTodo(), nil
+}
+
+// MigrateUpdatedAt backfills updated_at = created_at on any row written
+// before that field existed, so newly added sort/filter logic never sees
+// a zero time.
+func (s *MongoStore) MigrateUpdatedAt(ctx context.Context) error {
+	filter := bson.M{"updated_at": bson.M{"$exists": false}}
+	update := bson.A{bson.M{"$set": bson.M{"updated_at": "$created_at"}}}
+	_, err := s.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (s *MongoStore) Delete(ctx context.Context, id, ownerID string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	ownerOID, err := primitive.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"id": oid, "owner_id": ownerOID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnsureOwnerTitleIndex creates a unique index on (owner_id, title) so a
+// user cannot have two todos with the same title.
+func (s *MongoStore) EnsureOwnerTitleIndex(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "title", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Watch opens a change stream over the todo collection, scoped to the
+// given owner, optionally resuming from resumeAfter. Insert and update
+// events are matched on fullDocument.owner_id; delete events carry no
+// fullDocument (that requires document pre-images, Mongo 6+, to scope
+// precisely), so every delete is passed through here and the caller is
+// responsible for dropping the ones that don't belong to this owner.
+func (s *MongoStore) Watch(ctx context.Context, ownerID string, resumeAfter bson.Raw) (*mongo.ChangeStream, error) {
+	ownerOID, err := primitive.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"$or": bson.A{
+				bson.M{"fullDocument.owner_id": ownerOID},
+				bson.M{"operationType": "delete"},
+			},
+		}}},
+	}
+
+	streamOpts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if len(resumeAfter) > 0 {
+		streamOpts.SetResumeAfter(resumeAfter)
+	}
+
+	return s.collection.Watch(ctx, pipeline, streamOpts)
+}
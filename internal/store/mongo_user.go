@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoUserStore is the production UserStore backed by a MongoDB
+// collection.
+type MongoUserStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoUserStore returns a MongoUserStore backed by the given
+// collection.
+func NewMongoUserStore(collection *mongo.Collection) *MongoUserStore {
+	return &MongoUserStore{collection: collection}
+}
+
+type userModel struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	Username     string             `bson:"username"`
+	PasswordHash string             `bson:"password_hash"`
+}
+
+func (s *MongoUserStore) Create(ctx context.Context, username, passwordHash string) (User, error) {
+	user := userModel{
+		ID:           primitive.NewObjectID(),
+		Username:     username,
+		PasswordHash: passwordHash,
+	}
+
+	if _, err := s.collection.InsertOne(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return User{}, ErrUserExists
+		}
+		return User{}, err
+	}
+
+	return User{ID: user.ID.Hex(), Username: user.Username, PasswordHash: user.PasswordHash}, nil
+}
+
+func (s *MongoUserStore) GetByUsername(ctx context.Context, username string) (User, error) {
+	var user userModel
+	if err := s.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return User{}, ErrNotFound
+		}
+		return User{}, err
+	}
+
+	return User{ID: user.ID.Hex(), Username: user.Username, PasswordHash: user.PasswordHash}, nil
+}
+
+// EnsureUsernameIndex creates a unique index on username so registration
+// races can't create duplicate accounts.
+func (s *MongoUserStore) EnsureUsernameIndex(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
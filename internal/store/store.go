@@ -0,0 +1,93 @@
+// Package store defines the persistence layer used by the todo API so
+// handlers can run against either MongoDB or an in-memory backend.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get, Update and Delete when no todo or user
+// matches the given lookup.
+var ErrNotFound = errors.New("store: not found")
+
+// Todo is the storage-level representation of a todo item.
+type Todo struct {
+	ID        string
+	OwnerID   string
+	Title     string
+	Body      string
+	Completed bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DueDate   *time.Time
+	Priority  int
+	Tags      []string
+}
+
+// Filter describes the list query supported by getTodos: the owning
+// user, an optional completed flag, a title substring search, sorting,
+// and pagination.
+type Filter struct {
+	OwnerID   string
+	Completed *bool
+	Query     string
+	Sort      string // "created_at" or "title"
+	Order     string // "asc" or "desc"
+	Limit     int64
+	Offset    int64
+}
+
+// CreateInput carries the fields accepted when creating a new todo.
+type CreateInput struct {
+	OwnerID  string
+	Title    string
+	Body     string
+	DueDate  *time.Time
+	Priority int
+	Tags     []string
+}
+
+// UpdateInput carries the fields accepted when updating a todo. All
+// fields are replaced wholesale, matching the existing PUT semantics.
+type UpdateInput struct {
+	Title     string
+	Body      string
+	Completed bool
+	DueDate   *time.Time
+	Priority  int
+	Tags      []string
+}
+
+// TodoStore is implemented by every storage backend the API can run
+// against (MongoStore, MemoryStore, ...). Get, Update and Delete take
+// ownerID so a user can never act on another user's todo; they return
+// ErrNotFound both when the id is unknown and when it belongs to someone
+// else, so a caller can't distinguish the two.
+type TodoStore interface {
+	List(ctx context.Context, filter Filter) ([]Todo, error)
+	Count(ctx context.Context, filter Filter) (int64, error)
+	Get(ctx context.Context, id, ownerID string) (Todo, error)
+	Create(ctx context.Context, input CreateInput) (Todo, error)
+	Update(ctx context.Context, id, ownerID string, input UpdateInput) (Todo, error)
+	Delete(ctx context.Context, id, ownerID string) error
+}
+
+// User is the storage-level representation of a registered account.
+type User struct {
+	ID           string
+	Username     string
+	PasswordHash string
+}
+
+// ErrUserExists is returned by UserStore.Create when the username is
+// already taken.
+var ErrUserExists = errors.New("store: username already exists")
+
+// UserStore is implemented by every storage backend that can hold user
+// accounts for authentication.
+type UserStore interface {
+	Create(ctx context.Context, username, passwordHash string) (User, error)
+	GetByUsername(ctx context.Context, username string) (User, error)
+}
@@ -3,62 +3,82 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/thedevsaddam/renderer"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"simple-todo-app/internal/store"
 )
 
 var (
-	rnd    *renderer.Render
-	client *mongo.Client
-	db     *mongo.Database
+	rnd       *renderer.Render
+	client    *mongo.Client
+	db        *mongo.Database
+	todoStore store.TodoStore
+	userStore store.UserStore
+	jwtSecret []byte
 )
 
 const (
-	dbName         string = "go-todo-app"
-	collectionName string = "todo"
+	dbName              string = "go-todo-app"
+	collectionName      string = "todo"
+	usersCollectionName string = "users"
 )
 
 type (
-	TodoModel struct {
-		ID        primitive.ObjectID `bson:"id,omitempty"`
-		Title     string             `bson:"title"`
-		Completed bool               `bson:"completed"`
-		CreatedAt time.Time          `bson:"created_at"`
-	}
-
 	Todo struct {
-		ID        string    `json:"id"`
-		Title     string    `json:"title"`
-		Completed bool      `json:"completed"`
-		CreatedAt time.Time `json:"created_at"`
+		ID        string     `json:"id"`
+		Title     string     `json:"title"`
+		Body      string     `json:"body"`
+		Completed bool       `json:"completed"`
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt time.Time  `json:"updated_at"`
+		DueDate   *time.Time `json:"due_date,omitempty"`
+		Priority  int        `json:"priority"`
+		Tags      []string   `json:"tags"`
 	}
 
 	getTodoResponse struct {
 		Message string `json:"message"`
 		Data    []Todo `json:"data"`
+		Total   int64  `json:"total"`
+		Limit   int64  `json:"limit"`
+		Offset  int64  `json:"offset"`
+	}
+
+	getTodoByIDResponse struct {
+		Message string `json:"message"`
+		Data    Todo   `json:"data"`
 	}
 
 	CreateTodo struct {
-		Title string `json:"title"`
+		Title    string     `json:"title"`
+		Body     string     `json:"body"`
+		DueDate  *time.Time `json:"due_date,omitempty"`
+		Priority int        `json:"priority"`
+		Tags     []string   `json:"tags"`
 	}
 
 	UpdateTodo struct {
-		Title     string `json:"title"`
-		Completed bool   `json:"completed"`
+		Title     string     `json:"title"`
+		Body      string     `json:"body"`
+		Completed bool       `json:"completed"`
+		DueDate   *time.Time `json:"due_date,omitempty"`
+		Priority  int        `json:"priority"`
+		Tags      []string   `json:"tags"`
 	}
 )
 
@@ -66,21 +86,50 @@ func init() {
 	fmt.Println("Init function running")
 
 	rnd = renderer.New()
-	var err error
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+	if len(jwtSecret) == 0 {
+		log.Println("JWT_SECRET not set, using an insecure development default")
+		jwtSecret = []byte("dev-secret-do-not-use-in-production")
+	}
 
-	client, err = mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
-	if err != nil {
-		log.Fatal(err)
+	// STORAGE selects the backend: "mongo" (default) connects to a real
+	// MongoDB instance, "memory" runs against an in-process map so the
+	// app can boot (and be tested) without MongoDB.
+	if strings.ToLower(os.Getenv("STORAGE")) == "memory" {
+		todoStore = store.NewMemoryStore()
+		userStore = store.NewMemoryUserStore()
 	}
+}
 
-	if err := client.Ping(ctx, readpref.Primary()); err != nil {
-		log.Fatal(err)
+// connectMongo dials MongoDB with an exponential backoff retry loop (5
+// attempts, waiting 1s, 2s, 4s and 8s between them) so a Mongo restart
+// during deploy doesn't crash the server on startup.
+func connectMongo() (*mongo.Client, error) {
+	const maxAttempts = 5
+	backoff := 1 * time.Second
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		c, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+		if err == nil {
+			err = c.Ping(ctx, readpref.Primary())
+		}
+		cancel()
+		if err == nil {
+			return c, nil
+		}
+
+		lastErr = err
+		log.Printf("mongo connect attempt %d/%d failed: %v\n", attempt, maxAttempts, err)
+		if attempt < maxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
 	}
 
-	db = client.Database(dbName)
+	return nil, fmt.Errorf("could not connect to mongo after %d attempts: %w", maxAttempts, lastErr)
 }
 
 func homeHandler(rw http.ResponseWriter, r *http.Request) {
@@ -90,10 +139,70 @@ func homeHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// healthzHandler is the liveness probe: if the process can respond at
+// all, it's alive.
+func healthzHandler(rw http.ResponseWriter, r *http.Request) {
+	rnd.JSON(rw, http.StatusOK, renderer.M{"status": "ok"})
+}
+
+// readyzHandler is the readiness probe: only reports ready once Mongo is
+// reachable, so traffic isn't routed here before the dependency is up.
+// With no Mongo client (STORAGE=memory) it always reports ready.
+func readyzHandler(rw http.ResponseWriter, r *http.Request) {
+	if client == nil {
+		rnd.JSON(rw, http.StatusOK, renderer.M{"status": "ready"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		rnd.JSON(rw, http.StatusServiceUnavailable, renderer.M{
+			"status": "not ready",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	rnd.JSON(rw, http.StatusOK, renderer.M{"status": "ready"})
+}
+
 func main() {
+	if todoStore == nil {
+		c, err := connectMongo()
+		if err != nil {
+			log.Fatal(err)
+		}
+		client = c
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+		db = client.Database(dbName)
+		mongoStore := store.NewMongoStore(db.Collection(collectionName))
+		if err := mongoStore.MigrateUpdatedAt(ctx); err != nil {
+			log.Printf("failed to backfill updated_at: %v\n", err.Error())
+		}
+		if err := mongoStore.EnsureOwnerTitleIndex(ctx); err != nil {
+			log.Printf("failed to ensure owner/title index: %v\n", err.Error())
+		}
+		todoStore = mongoStore
+
+		mongoUserStore := store.NewMongoUserStore(db.Collection(usersCollectionName))
+		if err := mongoUserStore.EnsureUsernameIndex(ctx); err != nil {
+			log.Printf("failed to ensure username index: %v\n", err.Error())
+		}
+		userStore = mongoUserStore
+
+		cancel()
+	}
+
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
 	router.Get("/", homeHandler)
+	router.Get("/healthz", healthzHandler)
+	router.Get("/readyz", readyzHandler)
+	router.Mount("/auth", authHandlers())
 	router.Mount("/todo", todoHandlers())
 
 	server := &http.Server{
@@ -119,19 +228,23 @@ func main() {
 	sig := <-stopChan
 	log.Printf("signal received: %v\n", sig)
 
-	// disconnect mongo client from the database
-	if err := client.Disconnect(context.Background()); err != nil {
-		panic(err)
-	}
-
 	// create a context with a timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// shutdown the server gracefully
+	// shut down the HTTP server first so in-flight requests finish before
+	// the database connection they depend on goes away.
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server shutdown failed: %v\n", err)
 	}
+
+	// disconnect mongo client from the database, if one is in use
+	if client != nil {
+		if err := client.Disconnect(context.Background()); err != nil {
+			panic(err)
+		}
+	}
+
 	log.Println("Server shutdown gracefully")
 
 }
@@ -140,7 +253,10 @@ func todoHandlers() http.Handler {
 	router := chi.NewRouter()
 
 	router.Group(func(r chi.Router) {
+		r.Use(RequireUser)
 		r.Get("/", getTodos)
+		r.Get("/stream", streamTodos)
+		r.Get("/{id}", fetchSingleTodo)
 		r.Post("/", createTodo)
 		r.Put("/{id}", updateTodo)
 		r.Delete("/{id}", deleteTodo)
@@ -148,37 +264,121 @@ func todoHandlers() http.Handler {
 	return router
 }
 
+// parseTodoFilter translates the query parameters accepted by getTodos
+// (completed, q, sort, order, limit, offset) into a store.Filter.
+func parseTodoFilter(r *http.Request) store.Filter {
+	q := r.URL.Query()
+	filter := store.Filter{
+		OwnerID: userIDFromContext(r.Context()),
+		Query:   q.Get("q"),
+		Sort:    q.Get("sort"),
+		Order:   q.Get("order"),
+	}
+
+	if completed := q.Get("completed"); completed != "" {
+		if val, err := strconv.ParseBool(completed); err == nil {
+			filter.Completed = &val
+		}
+	}
+
+	if limit, err := strconv.ParseInt(q.Get("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+
+	if offset, err := strconv.ParseInt(q.Get("offset"), 10, 64); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	return filter
+}
+
+func toTodo(td store.Todo) Todo {
+	return Todo{
+		ID:        td.ID,
+		Title:     td.Title,
+		Body:      td.Body,
+		Completed: td.Completed,
+		CreatedAt: td.CreatedAt,
+		UpdatedAt: td.UpdatedAt,
+		DueDate:   td.DueDate,
+		Priority:  td.Priority,
+		Tags:      td.Tags,
+	}
+}
+
+// validateTodoFields applies the constraints shared by create and update:
+// priority must be 0-3, and tags (if any) must be non-empty strings.
+func validateTodoFields(priority int, tags []string) string {
+	if priority < 0 || priority > 3 {
+		return "priority must be between 0 and 3"
+	}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return "tags cannot be empty"
+		}
+	}
+	return ""
+}
+
 func getTodos(rw http.ResponseWriter, r *http.Request) {
-	var listFromDB []TodoModel
-	filter := bson.D{}
+	filter := parseTodoFilter(r)
 
-	cursor, err := db.Collection(collectionName).Find(context.Background(), filter)
+	total, err := todoStore.Count(r.Context(), filter)
 	if err != nil {
-		log.Printf("failed to retrieve records from db: %v\n", err.Error())
-		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+		log.Printf("failed to count records in store: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
 			"message": "Could not fetch the todo collection",
 			"error":   err.Error(),
 		})
 		return
 	}
 
-	todoList := []Todo{}
-	if err := cursor.All(context.Background(), &listFromDB); err != nil {
-		log.Fatal(err)
+	listFromStore, err := todoStore.List(r.Context(), filter)
+	if err != nil {
+		log.Printf("failed to retrieve records from store: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "Could not fetch the todo collection",
+			"error":   err.Error(),
+		})
+		return
 	}
 
-	// loop through the database list, convert TodoModel to JSON and append to the todoList array.
-	for _, td := range listFromDB {
-		todoList = append(todoList, Todo{
-			ID:        td.ID.Hex(),
-			Title:     td.Title,
-			Completed: td.Completed,
-			CreatedAt: td.CreatedAt,
-		})
+	todoList := []Todo{}
+	for _, td := range listFromStore {
+		todoList = append(todoList, toTodo(td))
 	}
+
 	rnd.JSON(rw, http.StatusOK, getTodoResponse{
 		Message: "Todos retrieved successfully ",
 		Data:    todoList,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	})
+}
+
+func fetchSingleTodo(rw http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+
+	todoFromStore, err := todoStore.Get(r.Context(), id, userIDFromContext(r.Context()))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			rnd.JSON(rw, http.StatusNotFound, renderer.M{
+				"message": "todo not found",
+			})
+			return
+		}
+		log.Printf("failed to retrieve record from store: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "Could not fetch the todo",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	rnd.JSON(rw, http.StatusOK, getTodoByIDResponse{
+		Message: "Todo retrieved successfully",
+		Data:    toTodo(todoFromStore),
 	})
 }
 
@@ -201,18 +401,31 @@ func createTodo(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// create a TodoModel for adding a todo to the database
-	todoModel := TodoModel{
-		ID:        primitive.NewObjectID(),
-		Title:     todoReq.Title,
-		Completed: false,
-		CreatedAt: time.Now(),
+	if msg := validateTodoFields(todoReq.Priority, todoReq.Tags); msg != "" {
+		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+			"message": msg,
+		})
+		return
+	}
+
+	if todoReq.DueDate != nil && !todoReq.DueDate.After(time.Now()) {
+		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+			"message": "due_date must be in the future",
+		})
+		return
 	}
 
-	// add the todo to the database
-	result, err := db.Collection(collectionName).InsertOne(r.Context(), todoModel)
+	// add the todo to the store
+	created, err := todoStore.Create(r.Context(), store.CreateInput{
+		OwnerID:  userIDFromContext(r.Context()),
+		Title:    todoReq.Title,
+		Body:     todoReq.Body,
+		DueDate:  todoReq.DueDate,
+		Priority: todoReq.Priority,
+		Tags:     todoReq.Tags,
+	})
 	if err != nil {
-		log.Printf("failed to insert data into database: %v\n", err.Error())
+		log.Printf("failed to insert data into the store: %v\n", err.Error())
 		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
 			"message": "failed to insert data into the database",
 			"error":   err.Error(),
@@ -222,7 +435,7 @@ func createTodo(rw http.ResponseWriter, r *http.Request) {
 
 	rnd.JSON(rw, http.StatusCreated, renderer.M{
 		"message": "todo created successfully",
-		"ID":      result.InsertedID,
+		"ID":      created.ID,
 	})
 }
 
@@ -230,16 +443,6 @@ func updateTodo(rw http.ResponseWriter, r *http.Request) {
 	// get the id from the url params
 	id := strings.TrimSpace(chi.URLParam(r, "id"))
 
-	res, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		log.Printf("the id param is not a valid hex value: %v\n", err.Error())
-		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
-			"message": "the id is invalid",
-			"error":   err.Error(),
-		})
-		return
-	}
-
 	var updateTodoReq UpdateTodo
 
 	if err := json.NewDecoder(r.Body).Decode(&updateTodoReq); err != nil {
@@ -253,13 +456,30 @@ func updateTodo(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// update the todo in the database
-	filter := bson.M{"id": res}
-	update := bson.M{"$set": bson.M{"title": updateTodoReq.Title, "completed": updateTodoReq.Completed}}
-	updateResult, err := db.Collection(collectionName).UpdateOne(r.Context(), filter, update)
+	if msg := validateTodoFields(updateTodoReq.Priority, updateTodoReq.Tags); msg != "" {
+		rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+			"message": msg,
+		})
+		return
+	}
 
+	// update the todo in the store
+	_, err := todoStore.Update(r.Context(), id, userIDFromContext(r.Context()), store.UpdateInput{
+		Title:     updateTodoReq.Title,
+		Body:      updateTodoReq.Body,
+		Completed: updateTodoReq.Completed,
+		DueDate:   updateTodoReq.DueDate,
+		Priority:  updateTodoReq.Priority,
+		Tags:      updateTodoReq.Tags,
+	})
 	if err != nil {
-		log.Printf("failed to update db collection: %v\n", err.Error())
+		if errors.Is(err, store.ErrNotFound) {
+			rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+				"message": "the id is invalid",
+			})
+			return
+		}
+		log.Printf("failed to update store: %v\n", err.Error())
 		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
 			"message": "Failed to update data in the database",
 			"error":   err.Error(),
@@ -269,30 +489,28 @@ func updateTodo(rw http.ResponseWriter, r *http.Request) {
 
 	rnd.JSON(rw, http.StatusOK, renderer.M{
 		"message": "Todo updated successfully",
-		"data":    updateResult.ModifiedCount,
 	})
 }
 
 func deleteTodo(rw http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	res, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		log.Printf("invalid id: %v\n", err.Error())
-		rnd.JSON(rw, http.StatusBadRequest, err.Error())
-		return
-	}
 
-	filter := bson.M{"id": res}
-	if deleteResult, err := db.Collection(collectionName).DeleteOne(r.Context(), filter); err != nil {
+	if err := todoStore.Delete(r.Context(), id, userIDFromContext(r.Context())); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+				"message": "the id is invalid",
+			})
+			return
+		}
 		log.Printf("could not delete item from database: %v\n", err.Error())
 		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
 			"message": "an error occurred while deleting todo item",
 			"error":   err.Error(),
 		})
-	} else {
-		rnd.JSON(rw, http.StatusOK, renderer.M{
-			"message": "item deleted successfully",
-			"data":    deleteResult,
-		})
+		return
 	}
+
+	rnd.JSON(rw, http.StatusOK, renderer.M{
+		"message": "item deleted successfully",
+	})
 }
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thedevsaddam/renderer"
+
+	"simple-todo-app/internal/auth"
+	"simple-todo-app/internal/store"
+)
+
+const (
+	testUserID  = "test-user"
+	otherUserID = "other-user"
+)
+
+// newTestRouter wires up the todo routes against a fresh MemoryStore so
+// handlers can be exercised end to end without a running MongoDB.
+func newTestRouter() http.Handler {
+	rnd = renderer.New()
+	jwtSecret = []byte("test-secret")
+	todoStore = store.NewMemoryStore()
+	return todoHandlers()
+}
+
+func authedRequest(method, target string, body []byte) *http.Request {
+	return authedRequestAs(testUserID, method, target, body)
+}
+
+func authedRequestAs(userID, method, target string, body []byte) *http.Request {
+	var req *http.Request
+	if body != nil {
+		req = httptest.NewRequest(method, target, bytes.NewReader(body))
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	token, _ := auth.NewToken(userID, jwtSecret)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestCreateAndFetchTodo(t *testing.T) {
+	router := newTestRouter()
+
+	body, _ := json.Marshal(CreateTodo{Title: "write tests"})
+	req := authedRequest(http.MethodPost, "/", body)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+	}
+
+	req = authedRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var resp getTodoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Total != 1 || len(resp.Data) != 1 {
+		t.Fatalf("expected 1 todo, got total=%d data=%d", resp.Total, len(resp.Data))
+	}
+	if resp.Data[0].Title != "write tests" {
+		t.Fatalf("expected title %q, got %q", "write tests", resp.Data[0].Title)
+	}
+}
+
+func TestTodoRoutesRequireAuth(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
+
+func TestFetchSingleTodoNotFound(t *testing.T) {
+	router := newTestRouter()
+
+	req := authedRequest(http.MethodGet, "/does-not-exist", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestUpdateAndDeleteTodo(t *testing.T) {
+	router := newTestRouter()
+
+	created, err := todoStore.Create(context.Background(), store.CreateInput{OwnerID: testUserID, Title: "finish chores"})
+	if err != nil {
+		t.Fatalf("failed to seed todo: %v", err)
+	}
+
+	body, _ := json.Marshal(UpdateTodo{Title: "finish chores", Completed: true})
+	req := authedRequest(http.MethodPut, "/"+created.ID, body)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	req = authedRequest(http.MethodDelete, "/"+created.ID, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	if _, err := todoStore.Get(context.Background(), created.ID, testUserID); err != store.ErrNotFound {
+		t.Fatalf("expected %v, got %v", store.ErrNotFound, err)
+	}
+}
+
+// TestTodoScopedToOwner asserts that one user can't read, list, update, or
+// delete a todo owned by another user.
+func TestTodoScopedToOwner(t *testing.T) {
+	router := newTestRouter()
+
+	created, err := todoStore.Create(context.Background(), store.CreateInput{OwnerID: testUserID, Title: "alice's todo"})
+	if err != nil {
+		t.Fatalf("failed to seed todo: %v", err)
+	}
+
+	req := authedRequestAs(otherUserID, http.MethodGet, "/"+created.ID, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d fetching another user's todo, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	body, _ := json.Marshal(UpdateTodo{Title: "stolen"})
+	req = authedRequestAs(otherUserID, http.MethodPut, "/"+created.ID, body)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d updating another user's todo, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	req = authedRequestAs(otherUserID, http.MethodDelete, "/"+created.ID, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d deleting another user's todo, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	req = authedRequestAs(otherUserID, http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp getTodoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Total != 0 || len(resp.Data) != 0 {
+		t.Fatalf("expected other user's list to be empty, got total=%d data=%d", resp.Total, len(resp.Data))
+	}
+
+	if _, err := todoStore.Get(context.Background(), created.ID, testUserID); err != nil {
+		t.Fatalf("expected original owner's todo to remain untouched: %v", err)
+	}
+}
+
+// TestHealthzAndReadyz asserts the liveness and readiness probes added
+// alongside the Mongo retry loop respond 200 when there's no client to
+// check against (e.g. running with STORAGE=memory).
+func TestHealthzAndReadyz(t *testing.T) {
+	newTestRouter()
+	client = nil
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	healthzHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d from /healthz, got %d", http.StatusOK, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec = httptest.NewRecorder()
+	readyzHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d from /readyz, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+// TestGetTodosFilterSortPaginate covers the query-param translation into
+// store.Filter added for GET /todo: the completed flag, the q substring
+// search, sort/order, and limit/offset pagination.
+func TestGetTodosFilterSortPaginate(t *testing.T) {
+	router := newTestRouter()
+
+	titles := []string{"alpha", "bravo", "charlie"}
+	for _, title := range titles {
+		body, _ := json.Marshal(CreateTodo{Title: title})
+		req := authedRequest(http.MethodPost, "/", body)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("failed to seed todo %q: status %d", title, rec.Code)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	listed, err := todoStore.List(context.Background(), store.Filter{OwnerID: testUserID, Sort: "title"})
+	if err != nil {
+		t.Fatalf("failed to look up seeded todos: %v", err)
+	}
+	var bravoID string
+	for _, td := range listed {
+		if td.Title == "bravo" {
+			bravoID = td.ID
+		}
+	}
+	if bravoID == "" {
+		t.Fatalf("expected to find seeded todo %q", "bravo")
+	}
+	body, _ := json.Marshal(UpdateTodo{Title: "bravo", Completed: true})
+	req := authedRequest(http.MethodPut, "/"+bravoID, body)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to mark %q completed: status %d", "bravo", rec.Code)
+	}
+
+	get := func(target string) getTodoResponse {
+		t.Helper()
+		req := authedRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected status %d, got %d", target, http.StatusOK, rec.Code)
+		}
+		var resp getTodoResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("GET %s: failed to decode response: %v", target, err)
+		}
+		return resp
+	}
+
+	if resp := get("/?completed=true"); resp.Total != 1 || resp.Data[0].Title != "bravo" {
+		t.Fatalf("completed=true: expected only %q, got %+v", "bravo", resp.Data)
+	}
+
+	if resp := get("/?completed=false"); resp.Total != 2 {
+		t.Fatalf("completed=false: expected 2 todos, got %d", resp.Total)
+	}
+
+	if resp := get("/?q=char"); resp.Total != 1 || resp.Data[0].Title != "charlie" {
+		t.Fatalf("q=char: expected only %q, got %+v", "charlie", resp.Data)
+	}
+
+	if resp := get("/?sort=title&order=asc"); len(resp.Data) != 3 ||
+		resp.Data[0].Title != "alpha" || resp.Data[1].Title != "bravo" || resp.Data[2].Title != "charlie" {
+		t.Fatalf("sort=title&order=asc: expected alpha, bravo, charlie in order, got %+v", resp.Data)
+	}
+
+	if resp := get("/?sort=title&order=desc"); len(resp.Data) != 3 ||
+		resp.Data[0].Title != "charlie" || resp.Data[2].Title != "alpha" {
+		t.Fatalf("sort=title&order=desc: expected charlie first and alpha last, got %+v", resp.Data)
+	}
+
+	if resp := get("/?sort=created_at&order=asc"); len(resp.Data) != 3 ||
+		resp.Data[0].Title != "alpha" || resp.Data[2].Title != "charlie" {
+		t.Fatalf("sort=created_at&order=asc: expected creation order, got %+v", resp.Data)
+	}
+
+	if resp := get("/?sort=title&order=asc&limit=1&offset=1"); resp.Total != 3 || len(resp.Data) != 1 || resp.Data[0].Title != "bravo" {
+		t.Fatalf("limit=1&offset=1: expected only %q (total 3), got total=%d data=%+v", "bravo", resp.Total, resp.Data)
+	}
+}
+
+// TestCreateTodoValidation asserts that the priority range and non-empty
+// tags rules applied by validateTodoFields, and the future-due-date check
+// alongside it, are enforced on create.
+func TestCreateTodoValidation(t *testing.T) {
+	router := newTestRouter()
+
+	past := time.Now().Add(-time.Hour)
+	cases := []struct {
+		name string
+		body CreateTodo
+	}{
+		{"priority too low", CreateTodo{Title: "t", Priority: -1}},
+		{"priority too high", CreateTodo{Title: "t", Priority: 4}},
+		{"blank tag", CreateTodo{Title: "t", Tags: []string{"  "}}},
+		{"due date in the past", CreateTodo{Title: "t", DueDate: &past}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, _ := json.Marshal(tc.body)
+			req := authedRequest(http.MethodPost, "/", body)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
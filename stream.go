@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/thedevsaddam/renderer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"simple-todo-app/internal/store"
+)
+
+const streamKeepaliveInterval = 15 * time.Second
+
+// todoWatcher is implemented by storage backends that can stream live
+// changes; only MongoStore currently does.
+type todoWatcher interface {
+	Watch(ctx context.Context, ownerID string, resumeAfter bson.Raw) (*mongo.ChangeStream, error)
+}
+
+// changeEvent pairs a decoded change stream document with the resume
+// token as of that point in the stream, so it can be handed to the
+// client in a form it can actually replay via ?resumeAfter=.
+type changeEvent struct {
+	doc         bson.M
+	resumeToken bson.Raw
+}
+
+// changeEventDocumentID extracts the hex _id of the document a change
+// event refers to, from documentKey (present on every event, including
+// deletes), so events can be correlated across operation types.
+func changeEventDocumentID(event bson.M) string {
+	documentKey, ok := event["documentKey"].(bson.M)
+	if !ok {
+		return ""
+	}
+	oid, ok := documentKey["_id"].(primitive.ObjectID)
+	if !ok {
+		return ""
+	}
+	return oid.Hex()
+}
+
+// streamTodos streams insert/update/delete events for the caller's own
+// todos as Server-Sent Events. Each event's SSE "id:" field carries the
+// resume token hex-encoded; the client can reconnect after a drop by
+// passing that value back via ?resumeAfter=<hex token> (or simply relying
+// on a browser EventSource's automatic Last-Event-ID header).
+func streamTodos(rw http.ResponseWriter, r *http.Request) {
+	watcher, ok := todoStore.(todoWatcher)
+	if !ok {
+		rnd.JSON(rw, http.StatusNotImplemented, renderer.M{
+			"message": "live updates are not supported by the current storage backend",
+		})
+		return
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "streaming unsupported by this server",
+		})
+		return
+	}
+
+	var resumeAfter bson.Raw
+	tokenHex := r.URL.Query().Get("resumeAfter")
+	if tokenHex == "" {
+		tokenHex = r.Header.Get("Last-Event-ID")
+	}
+	if tokenHex != "" {
+		raw, err := hex.DecodeString(tokenHex)
+		if err != nil {
+			rnd.JSON(rw, http.StatusBadRequest, renderer.M{
+				"message": "invalid resumeAfter token",
+			})
+			return
+		}
+		resumeAfter = raw
+	}
+
+	ctx := r.Context()
+	ownerID := userIDFromContext(ctx)
+	stream, err := watcher.Watch(ctx, ownerID, resumeAfter)
+	if err != nil {
+		log.Printf("failed to open change stream: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "failed to open change stream",
+			"error":   err.Error(),
+		})
+		return
+	}
+	defer stream.Close(context.Background())
+
+	// ownedIDs tracks the ids of this caller's own todos, so a delete
+	// (which carries no fullDocument to match owner_id against) can still
+	// be dropped unless it belongs to one of them. It's seeded from the
+	// todos that exist when the stream opens, then kept up to date from
+	// owner-scoped insert/update events seen afterwards - otherwise a
+	// delete of any todo that predates the connection would never match.
+	existing, err := todoStore.List(ctx, store.Filter{OwnerID: ownerID})
+	if err != nil {
+		log.Printf("failed to seed change stream ownership: %v\n", err.Error())
+		rnd.JSON(rw, http.StatusInternalServerError, renderer.M{
+			"message": "failed to open change stream",
+			"error":   err.Error(),
+		})
+		return
+	}
+	ownedIDs := make(map[string]struct{}, len(existing))
+	for _, td := range existing {
+		ownedIDs[td.ID] = struct{}{}
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := make(chan changeEvent)
+	streamErrs := make(chan error, 1)
+	go func() {
+		defer close(events)
+		for stream.Next(ctx) {
+			var doc bson.M
+			if err := stream.Decode(&doc); err != nil {
+				streamErrs <- err
+				return
+			}
+
+			docID := changeEventDocumentID(doc)
+			if doc["operationType"] == "delete" {
+				if docID == "" {
+					continue
+				}
+				if _, owned := ownedIDs[docID]; !owned {
+					continue
+				}
+				delete(ownedIDs, docID)
+			} else if docID != "" {
+				ownedIDs[docID] = struct{}{}
+			}
+
+			events <- changeEvent{doc: doc, resumeToken: stream.ResumeToken()}
+		}
+		if err := stream.Err(); err != nil {
+			streamErrs <- err
+		}
+	}()
+
+	keepalive := time.NewTicker(streamKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-keepalive.C:
+			fmt.Fprint(rw, ": keepalive\n\n")
+			flusher.Flush()
+
+		case err := <-streamErrs:
+			log.Printf("change stream error: %v\n", err.Error())
+			return
+
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			payload, err := json.Marshal(event.doc)
+			if err != nil {
+				log.Printf("failed to marshal change event: %v\n", err.Error())
+				continue
+			}
+			// The "id:" field carries the resume token as the hex bytes
+			// SetResumeAfter expects, not the JSON-marshaled _id in data:
+			// above, so a reconnect via ?resumeAfter=<that hex> (or a
+			// browser EventSource's automatic Last-Event-ID) round-trips.
+			fmt.Fprintf(rw, "id: %s\ndata: %s\n\n", hex.EncodeToString(event.resumeToken), payload)
+			flusher.Flush()
+		}
+	}
+}